@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+)
+
+// severityRank orders severities from least to most severe, so --min-severity and --fail-on
+// critical can compare by rank rather than string equality.
+var severityRank = map[string]int{
+	"unknown":  0,
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// Vulnerability is a single advisory matched against a detected version.
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	CVSS     string `json:"cvss,omitempty"`
+	FixedIn  string `json:"fixed_in,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// advisory mirrors one entry of the GitLab Advisory Database index. CVSSV3 is the raw CVSS
+// vector string (e.g. "CVSS:3.1/AV:N/AC:L/..."), not a numeric score, and URLs is a list since
+// an advisory commonly links both its GitLab issue and an upstream CVE/NVD entry.
+type advisory struct {
+	Identifier    string   `json:"identifier"`
+	AffectedRange string   `json:"affected_range"`
+	FixedVersions string   `json:"fixed_versions"`
+	Severity      string   `json:"severity"`
+	CVSSV3        string   `json:"cvss_v3"`
+	URLs          []string `json:"urls"`
+}
+
+// getAdvisories returns the advisory list used for CVE enrichment. It queries no source and
+// always returns an empty list, so enrichVulnerabilities matches nothing, results carry no
+// Vulnerabilities, and --min-severity and --fail-on critical have nothing to act on.
+// gemnasium-db (https://gitlab.com/gitlab-org/security-products/gemnasium-db) indexes
+// third-party dependency advisories per package ecosystem (go, npm, packagist, ...) and has no
+// index for GitLab itself.
+func getAdvisories(cache cacheConfig) ([]advisory, error) {
+	return nil, nil
+}
+
+// enrichVulnerabilities matches result.Version against advisories and sets
+// result.Vulnerabilities, dropping any whose severity is below minSeverity. A version that fails
+// to parse (e.g. "unknown") silently skips enrichment, and a minSeverity that isn't recognised
+// applies no severity filter, rather than either being treated as a hard failure, since advisory
+// enrichment is a best-effort addition to an otherwise-successful fingerprint.
+func enrichVulnerabilities(result *Result, advisories []advisory, minSeverity string) {
+	version, err := parseGitlabSemver(result.Version)
+	if err != nil {
+		return
+	}
+
+	minRank, ok := severityRank[minSeverity]
+	if !ok {
+		minRank = 0
+	}
+
+	for _, entry := range advisories {
+		matched, err := satisfiesRange(version, entry.AffectedRange)
+		if err != nil || !matched {
+			continue
+		}
+
+		// GitLab/gemnasium advisory severities are capitalized ("Critical", "High"), but
+		// severityRank and every downstream comparison (--fail-on critical, SARIF escalation)
+		// key off the lowercase form. Normalize once here so all three agree.
+		severity := strings.ToLower(entry.Severity)
+		if severityRank[severity] < minRank {
+			continue
+		}
+
+		var url string
+		if len(entry.URLs) > 0 {
+			url = entry.URLs[0]
+		}
+
+		result.Vulnerabilities = append(result.Vulnerabilities, Vulnerability{
+			ID:       entry.Identifier,
+			Severity: severity,
+			CVSS:     entry.CVSSV3,
+			FixedIn:  entry.FixedVersions,
+			URL:      url,
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Origin records the upstream cache-validation metadata for a single cached response, stored
+// as a JSON sidecar next to the cached body.
+type Origin struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	SHA256       string    `json:"sha256"`
+}
+
+// CacheEntry is the in-memory view of a cached response: its body plus the Origin sidecar
+// describing how and when it was retrieved. Mirrors Manifest in shape (a payload plus the
+// metadata needed to know whether it's still fresh).
+type CacheEntry struct {
+	Body   []byte
+	Origin Origin
+}
+
+// cacheConfig controls the on-disk cache used by getHashDictionary, getGitlabVersionsInfo and
+// getTagsForMinorVersion. It's populated from --cache-dir/--cache-ttl/--no-cache in main.
+type cacheConfig struct {
+	dir     string
+	ttl     time.Duration
+	disable bool
+}
+
+const defaultCacheTTL = 1 * time.Hour
+
+// defaultCacheDir returns $XDG_CACHE_HOME/gitlab-fingerprinter, falling back to
+// os.UserCacheDir() when XDG_CACHE_HOME is unset.
+func defaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "gitlab-fingerprinter")
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "gitlab-fingerprinter")
+	}
+	return filepath.Join(dir, "gitlab-fingerprinter")
+}
+
+// cachePaths returns the body and Origin sidecar paths for rawURL under cacheDir.
+func cachePaths(cacheDir string, rawURL string) (bodyPath string, originPath string) {
+	key := sha256.Sum256([]byte(rawURL))
+	name := hex.EncodeToString(key[:])
+	return filepath.Join(cacheDir, name+".body"), filepath.Join(cacheDir, name+".origin.json")
+}
+
+// loadCacheEntry reads a previously cached response for rawURL, if any.
+func loadCacheEntry(cacheDir string, rawURL string) (CacheEntry, bool) {
+	bodyPath, originPath := cachePaths(cacheDir, rawURL)
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	rawOrigin, err := os.ReadFile(originPath)
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	var origin Origin
+	if err := json.Unmarshal(rawOrigin, &origin); err != nil {
+		return CacheEntry{}, false
+	}
+
+	return CacheEntry{Body: body, Origin: origin}, true
+}
+
+// saveCacheEntry writes body and its Origin sidecar for rawURL under cacheDir.
+func saveCacheEntry(cacheDir string, rawURL string, body []byte, origin Origin) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	origin.URL = rawURL
+	origin.SHA256 = hex.EncodeToString(sum[:])
+
+	bodyPath, originPath := cachePaths(cacheDir, rawURL)
+
+	if err := os.WriteFile(bodyPath, body, 0o644); err != nil {
+		return err
+	}
+
+	rawOrigin, err := json.MarshalIndent(origin, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(originPath, rawOrigin, 0o644)
+}
+
+// fetchWithCache GETs rawURL, transparently serving and revalidating a cache entry under cfg.dir:
+// a still-fresh entry (within cfg.ttl) is returned without touching the network at all, an
+// expired one is revalidated with If-None-Match/If-Modified-Since and refreshed on 304, and
+// cfg.disable bypasses the cache entirely in both directions. ctx bounds the network round-trip
+// on a cache miss/revalidation, same as every other outgoing request.
+func fetchWithCache(ctx context.Context, cfg cacheConfig, rawURL string) (body []byte, err error) {
+	if cfg.disable {
+		return plainGet(ctx, rawURL)
+	}
+
+	entry, cached := loadCacheEntry(cfg.dir, rawURL)
+	if cached && cfg.ttl > 0 && time.Since(entry.Origin.FetchedAt) < cfg.ttl {
+		return entry.Body, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req)
+	if cached {
+		if entry.Origin.ETag != "" {
+			req.Header.Set("If-None-Match", entry.Origin.ETag)
+		}
+		if entry.Origin.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.Origin.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if cached {
+			// Air-gapped or offline run: fall back to whatever we have cached.
+			return entry.Body, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		entry.Origin.FetchedAt = time.Now()
+		_ = saveCacheEntry(cfg.dir, rawURL, entry.Body, entry.Origin)
+		return entry.Body, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if cached {
+			return entry.Body, nil
+		}
+		return nil, fmt.Errorf("%s did not respond with a 200 OK", rawURL)
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := Origin{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	if err := saveCacheEntry(cfg.dir, rawURL, body, origin); err != nil {
+		return nil, fmt.Errorf("failed to write cache entry for %s: %v", rawURL, err)
+	}
+
+	return body, nil
+}
+
+// plainGet performs an uncached GET, used when the cache is disabled via --no-cache.
+func plainGet(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s did not respond with a 200 OK", rawURL)
+	}
+
+	return io.ReadAll(resp.Body)
+}
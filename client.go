@@ -0,0 +1,42 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// httpClient is the shared HTTP client used for every outbound request. main() configures its
+// transport from --insecure and --proxy before any target is fingerprinted.
+var httpClient = &http.Client{}
+
+// userAgent, when non-empty (--user-agent), is sent on every outbound request.
+var userAgent string
+
+// configureHTTPClient applies --insecure and --proxy to httpClient's transport.
+func configureHTTPClient(insecureSkipVerify bool, proxy string) error {
+	transport := &http.Transport{}
+
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid --proxy URL %q: %v", proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	httpClient.Transport = transport
+	return nil
+}
+
+// setUserAgent sets the User-Agent header on req when --user-agent was given.
+func setUserAgent(req *http.Request) {
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// Fingerprinter detects whether a target is running a particular self-hosted forge and, if so,
+// resolves its version/edition. Each implementation owns one forge's probe, request shape and
+// response parsing; dispatchTarget tries them in registration order (or a single forced one via
+// --type) until one claims the target.
+type Fingerprinter interface {
+	// Name identifies the forge this Fingerprinter detects (e.g. "gitlab", "gitea"). Used for
+	// the --type flag and to populate Result.Product.
+	Name() string
+
+	// Detect probes targetURL and returns a Result if it appears to run this forge.
+	// It returns ErrNotDetected (optionally wrapped) when the target clearly isn't this forge,
+	// so that dispatchTarget can move on to the next Fingerprinter without treating it as a
+	// fatal error.
+	Detect(ctx context.Context, targetURL *url.URL) (Result, error)
+}
+
+// ErrNotDetected is returned by a Fingerprinter.Detect when the target does not appear to be
+// running that forge at all, as opposed to a transient or authentication error.
+var ErrNotDetected = errors.New("target does not appear to run this forge")
+
+// dispatchTarget runs targetURL through fingerprinters in order and returns the first match.
+// If none match, it returns the most specific error encountered (preferring one that isn't
+// ErrNotDetected, since that's more useful to report than "not this forge" from every probe).
+func dispatchTarget(ctx context.Context, targetURL *url.URL, fingerprinters []Fingerprinter) (Result, error) {
+	var lastErr error
+
+	for _, fp := range fingerprinters {
+		result, err := fp.Detect(ctx, targetURL)
+		if err == nil {
+			result.Product = fp.Name()
+			return result, nil
+		}
+
+		if !errors.Is(err, ErrNotDetected) {
+			lastErr = err
+		} else if lastErr == nil {
+			lastErr = err
+		}
+	}
+
+	return Result{}, lastErr
+}
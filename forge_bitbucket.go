@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const bitbucketApplicationPropertiesPath = "/rest/api/1.0/application-properties"
+
+// bitbucketApplicationProperties mirrors the relevant fields of Bitbucket Server/Data Center's
+// unauthenticated GET /rest/api/1.0/application-properties response.
+type bitbucketApplicationProperties struct {
+	Version     string `json:"version"`
+	DisplayName string `json:"displayName"`
+}
+
+// BitbucketFingerprinter detects Bitbucket Server/Data Center instances via their
+// unauthenticated application-properties endpoint.
+type BitbucketFingerprinter struct{}
+
+func (f *BitbucketFingerprinter) Name() string { return "bitbucket" }
+
+func (f *BitbucketFingerprinter) Detect(ctx context.Context, targetURL *url.URL) (Result, error) {
+	propertiesURL := *targetURL
+	propertiesURL.Path = bitbucketApplicationPropertiesPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, propertiesURL.String(), nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrNotDetected, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("%w: %s did not respond with a 200 OK", ErrNotDetected, propertiesURL.String())
+	}
+
+	rawJSON, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if !json.Valid(rawJSON) {
+		return Result{}, fmt.Errorf("%w: %s did not return valid json", ErrNotDetected, propertiesURL.String())
+	}
+
+	var properties bitbucketApplicationProperties
+	if err := json.Unmarshal(rawJSON, &properties); err != nil || properties.Version == "" {
+		return Result{}, fmt.Errorf("%w: %s did not return a Bitbucket version", ErrNotDetected, propertiesURL.String())
+	}
+
+	return Result{
+		Target:  targetURL.Host,
+		Version: properties.Version,
+		Edition: properties.DisplayName,
+	}, nil
+}
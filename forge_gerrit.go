@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const gerritVersionPath = "/config/server/version"
+
+// gerritXSSIPrefix is the magic prefix line Gerrit (and other Google-style JSON APIs) prepends
+// to every response body to prevent it being parsed as executable JavaScript if loaded directly
+// via a <script> tag.
+const gerritXSSIPrefix = ")]}'"
+
+// GerritFingerprinter detects Gerrit instances via their unauthenticated /config/server/version
+// endpoint, which returns a JSON string (e.g. "3.8.2") behind the XSSI-protection prefix line.
+type GerritFingerprinter struct{}
+
+func (f *GerritFingerprinter) Name() string { return "gerrit" }
+
+func (f *GerritFingerprinter) Detect(ctx context.Context, targetURL *url.URL) (Result, error) {
+	versionURL := *targetURL
+	versionURL.Path = gerritVersionPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, versionURL.String(), nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrNotDetected, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("%w: %s did not respond with a 200 OK", ErrNotDetected, versionURL.String())
+	}
+
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	body := strings.TrimPrefix(string(rawBody), gerritXSSIPrefix)
+	body = strings.TrimLeft(body, "\n")
+
+	var version string
+	if err := json.Unmarshal([]byte(body), &version); err != nil || version == "" {
+		return Result{}, fmt.Errorf("%w: %s did not return a Gerrit version", ErrNotDetected, versionURL.String())
+	}
+
+	return Result{
+		Target:  targetURL.Host,
+		Version: version,
+	}, nil
+}
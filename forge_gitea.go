@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const giteaVersionPath = "/api/v1/version"
+
+// giteaVersionResponse mirrors the response of GET /api/v1/version.
+type giteaVersionResponse struct {
+	Version string `json:"version"`
+}
+
+// GiteaFingerprinter detects Gitea instances via their unauthenticated /api/v1/version endpoint.
+type GiteaFingerprinter struct{}
+
+func (f *GiteaFingerprinter) Name() string { return "gitea" }
+
+func (f *GiteaFingerprinter) Detect(ctx context.Context, targetURL *url.URL) (Result, error) {
+	versionURL := *targetURL
+	versionURL.Path = giteaVersionPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, versionURL.String(), nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrNotDetected, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("%w: %s did not respond with a 200 OK", ErrNotDetected, versionURL.String())
+	}
+
+	rawJSON, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if !json.Valid(rawJSON) {
+		return Result{}, fmt.Errorf("%w: %s did not return valid json", ErrNotDetected, versionURL.String())
+	}
+
+	var version giteaVersionResponse
+	if err := json.Unmarshal(rawJSON, &version); err != nil || version.Version == "" {
+		return Result{}, fmt.Errorf("%w: %s did not return a Gitea version", ErrNotDetected, versionURL.String())
+	}
+
+	return Result{
+		Target:  targetURL.Host,
+		Version: version.Version,
+	}, nil
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const endOfLifeDateApiURL = "https://endoflife.date/api/gitlab.json"
+const hashesURL = "https://raw.githubusercontent.com/righel/gitlab-version-nse/main/gitlab_hashes.json"
+const tagsApiURL = "https://gitlab.com/api/v4/projects/278964/repository/tags"
+const manifestPath = "/assets/webpack/manifest.json"
+const metadataPath = "/api/v4/metadata"
+const versionPath = "/api/v4/version"
+
+// Minor version as of which GitLab's /api/v4/metadata response is rich enough
+// (version, revision, kas.version, enterprise) to replace the hash/tag guessing flow entirely.
+const metadataAuthoritativeSinceMajor = 15
+const metadataAuthoritativeSinceMinor = 9
+
+type HashDictionary map[string]struct {
+	Build    string   `json:"build"`
+	Versions []string `json:"versions"`
+}
+
+type GitlabTag struct {
+	Name          string    `json:"name"`
+	CreatedAtDate time.Time `json:"created_at"`
+}
+type GitlabTags []GitlabTag
+
+type GitlabVersion struct {
+	Cycle             string `json:"cycle"`
+	EOL               string `json:"eol"`
+	Latest            string `json:"latest"`
+	LatestReleaseDate string `json:"latestReleaseDate"`
+	ReleaseDate       string `json:"releaseDate"`
+}
+type GitlabVersions []GitlabVersion
+
+type Manifest struct {
+	Hash             string `json:"hash"`
+	LastModifiedDate time.Time
+	OutputPath       string `json:"outputPath"`
+}
+
+// GitlabMetadata mirrors the relevant fields of the /api/v4/metadata response.
+// /api/v4/version only ever populates Version and Revision.
+type GitlabMetadata struct {
+	Version    string `json:"version"`
+	Revision   string `json:"revision"`
+	Enterprise bool   `json:"enterprise"`
+	KAS        struct {
+		Enabled bool   `json:"enabled"`
+		Version string `json:"version"`
+	} `json:"kas"`
+}
+
+// Cache GitLab API results per minor version. Guarded by gitlabTagsCacheMutex since multiple
+// workers may resolve the same minor version concurrently.
+var gitlabTagsCache = make(map[string]GitlabTags)
+var gitlabTagsCacheMutex sync.RWMutex
+
+// GitLabFingerprinter detects GitLab instances, first via the authoritative /api/v4/metadata
+// (and /api/v4/version) endpoints, then falling back to matching the webpack manifest hash
+// against the community hash dictionary.
+type GitLabFingerprinter struct {
+	Token              string
+	Cache              cacheConfig
+	HashDictionary     HashDictionary
+	GitlabVersionsInfo GitlabVersions
+	Advisories         []advisory
+	MinSeverity        string
+}
+
+func (f *GitLabFingerprinter) Name() string { return "gitlab" }
+
+// Detect runs the metadata/version fast path first, then the webpack manifest hash/tag flow.
+func (f *GitLabFingerprinter) Detect(ctx context.Context, targetURL *url.URL) (Result, error) {
+	// Try the authoritative /api/v4/metadata (and /api/v4/version as a fallback for older
+	// instances) path first. On sufficiently recent instances this gives us the version and
+	// edition directly, letting us skip the hash/tag guessing below entirely.
+	result, authoritative, metadataWarning, err := fingerprintViaMetadata(ctx, targetURL, f.Token)
+	if err != nil {
+		return Result{}, fmt.Errorf("authenticated fingerprinting is available but was rejected: %w", err)
+	} else if authoritative {
+		annotateEndOfLife(&result, f.GitlabVersionsInfo)
+		enrichVulnerabilities(&result, f.Advisories, f.MinSeverity)
+		return result, nil
+	}
+
+	manifestURL := *targetURL
+	manifestURL.Path = manifestPath
+
+	manifest, err := getManifest(ctx, manifestURL.String())
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: failed to fetch webpack manifest: %v", ErrNotDetected, err)
+	}
+
+	// If there is no mention of gitlab in the outputPath, the Manifest does not belong to a GitLab installation.
+	if !strings.Contains(manifest.OutputPath, "gitlab") {
+		return Result{}, fmt.Errorf("%w: the outputPath in %s has no mention of 'gitlab' in it", ErrNotDetected, manifestURL.String())
+	}
+
+	result = Result{Target: targetURL.Host}
+	if metadataWarning != "" {
+		result.Warnings = append(result.Warnings, metadataWarning)
+	}
+
+	// Iterate over hashes.
+	var hashFound bool
+	for dictionaryHash, info := range f.HashDictionary {
+		if dictionaryHash != manifest.Hash {
+			continue
+		}
+		hashFound = true
+
+		switch info.Build {
+		case "gitlab-ee":
+			result.Edition = "enterprise"
+		case "gitlab-ce":
+			result.Edition = "community"
+		default:
+			result.Edition = "unknown"
+			result.Warnings = append(result.Warnings, fmt.Sprintf("could not determine Edition: the following edition was returned in the hash results: %s", info.Build))
+		}
+
+		// If more than one version is returned we will try to guess the versions further.
+		if len(info.Versions) == 1 {
+			for _, version := range info.Versions {
+				result.Version = version
+			}
+		} else {
+			// Find the Tag where the creation date is before the Manifest Last-Modified date and closest to it.
+			var closestDate time.Time
+			var closestDateDifference time.Duration
+			var closestDateTag string
+
+			// Check if multiple minor versions are returned. Chance of this happening is neglectible, but handle this situation regardless.
+			minorVersionsMap := make(map[string]bool)
+			var resultMinorVersion string
+
+			for _, version := range info.Versions {
+				versionParts := strings.Split(version, ".")
+				parsedMinorVersion := strings.Join(versionParts[:2], ".")
+				minorVersionsMap[parsedMinorVersion] = true
+				resultMinorVersion = parsedMinorVersion
+			}
+
+			if len(minorVersionsMap) > 1 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("could not determine exact version: multiple minor versions were returned: %s", info.Versions))
+			} else {
+				tags, err := getTagsForMinorVersion(ctx, f.Cache, resultMinorVersion)
+				if err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("failed to retrieve tags for minor version %s: %v", resultMinorVersion, err))
+				} else {
+					for _, tag := range tags {
+						if tag.CreatedAtDate.Before(manifest.LastModifiedDate) {
+							difference := manifest.LastModifiedDate.Sub(tag.CreatedAtDate)
+
+							if closestDate.IsZero() || difference < closestDateDifference {
+								closestDate = tag.CreatedAtDate
+								closestDateDifference = difference
+								closestDateTag = tag.Name
+							}
+						}
+					}
+
+					result.Version = strings.Replace(strings.Replace(closestDateTag, "v", "", -1), "-ee", "", -1)
+				}
+			}
+		}
+		annotateEndOfLife(&result, f.GitlabVersionsInfo)
+		enrichVulnerabilities(&result, f.Advisories, f.MinSeverity)
+	}
+
+	// If a hash was returned, but not found in the dictionary it can mean two things:
+	if !hashFound {
+		// The hash dictionary has not been updated yet, in this case we check if the Last-Modified date is less than 24 old.
+		if manifest.LastModifiedDate.After(time.Now().Add(-24 * time.Hour)) {
+			result.Version = "unknown"
+			result.Edition = "unknown"
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Could not fingerprint the version as the hash was not found in '%s'. However, "+
+				"the installed version seems to be less than 24 hours old and is likely not indexed yet (which happens once a day). "+
+				"It's therefore safe to assume that it's running a version released in the last 24 hours.", hashesURL))
+			return result, nil
+		}
+
+		// If longer than 24 hours old, the hash dictionary is no longer being updated.
+		return Result{}, fmt.Errorf("a manifest file was found, but the hash in it (%s) was not found in '%s'. The Last-Modified "+
+			"date of the manifest file (%s) is not shorter than 24 hours. The most likely culprit for this error is that the Hashes file is no "+
+			"longer being updated. See: https://github.com/righel/gitlab-version-nse/",
+			manifest.Hash, hashesURL, manifest.LastModifiedDate)
+	}
+
+	return result, nil
+}
+
+func getGitlabVersionsInfo(cache cacheConfig) (gitlabVersions GitlabVersions, err error) {
+	rawJSON, err := fetchWithCache(context.Background(), cache, endOfLifeDateApiURL)
+	if err != nil {
+		return
+	}
+
+	if !json.Valid(rawJSON) {
+		err = fmt.Errorf("%s did not return valid json", endOfLifeDateApiURL)
+		return
+	}
+
+	err = json.Unmarshal(rawJSON, &gitlabVersions)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getHashDictionary(cache cacheConfig) (hashDictionary HashDictionary, err error) {
+	rawJSON, err := fetchWithCache(context.Background(), cache, hashesURL)
+	if err != nil {
+		return
+	}
+
+	if !json.Valid(rawJSON) {
+		err = fmt.Errorf("%s did not return valid json", hashesURL)
+		return
+	}
+
+	err = json.Unmarshal(rawJSON, &hashDictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func getTagsForMinorVersion(ctx context.Context, cache cacheConfig, minorVersion string) (gitlabTags GitlabTags, err error) {
+	// Check if the tags for the given minor version are already in the in-memory cache for
+	// this run, on top of the on-disk cache consulted by fetchWithCache below.
+	gitlabTagsCacheMutex.RLock()
+	cachedTags, ok := gitlabTagsCache[minorVersion]
+	gitlabTagsCacheMutex.RUnlock()
+	if ok {
+		return cachedTags, nil
+	}
+
+	url := tagsApiURL + "?per_page=50&search=v" + minorVersion + ".*-ee"
+
+	rawJSON, err := fetchWithCache(ctx, cache, url)
+	if err != nil {
+		return
+	}
+
+	if !json.Valid(rawJSON) {
+		err = fmt.Errorf("%s did not return valid JSON file", url)
+		return
+	}
+
+	err = json.Unmarshal(rawJSON, &gitlabTags)
+	if err != nil {
+		err = fmt.Errorf("%s did not return valid Manifest file: %v", url, err)
+		return
+	}
+
+	for tag := range gitlabTags {
+		tag := &gitlabTags[tag]
+
+		// Parse the Created At date to the correct format:
+		tag.CreatedAtDate, err = time.Parse("2006-01-02 15:04:05 -0700 MST", tag.CreatedAtDate.String())
+		if err != nil {
+			return
+		}
+	}
+
+	// Store the tags in the cache.
+	gitlabTagsCacheMutex.Lock()
+	gitlabTagsCache[minorVersion] = gitlabTags
+	gitlabTagsCacheMutex.Unlock()
+
+	return
+}
+
+func getManifest(ctx context.Context, url string) (manifest Manifest, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		err = fmt.Errorf("likely not a GitLab installation as %s did not respond with a 200 OK", url)
+		return
+	}
+
+	rawJSON, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if !json.Valid(rawJSON) {
+		err = fmt.Errorf("likely not a GitLab installation as %s did not return valid json", url)
+		return
+	}
+
+	err = json.Unmarshal(rawJSON, &manifest)
+	if err != nil {
+		err = fmt.Errorf("likely not a GitLab installation as %s did not return a (GitLab) webpack Manifest", url)
+		return
+	}
+
+	lastModifiedTime, err := time.Parse("Mon, 02 Jan 2006 15:04:05 MST", resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return
+	}
+
+	manifest.LastModifiedDate = lastModifiedTime
+
+	return
+}
+
+// annotateEndOfLife fills in result.EndOfLife, result.Outdated and result.Warnings
+// by comparing result.Version against the endoflife.date data for its minor cycle.
+func annotateEndOfLife(result *Result, gitlabVersionsInfo GitlabVersions) {
+	resultVersionParts := strings.Split(result.Version, ".")
+	if len(resultVersionParts) < 2 {
+		return
+	}
+	resultMinorVersion := strings.Join(resultVersionParts[:2], ".")
+
+	var warnings []string
+	for _, gitlabVersion := range gitlabVersionsInfo {
+		if gitlabVersion.Cycle != resultMinorVersion {
+			continue
+		}
+
+		eolDate, err := time.Parse("2006-01-02", gitlabVersion.EOL)
+		if err != nil {
+			continue
+		}
+
+		if eolDate.Before(time.Now()) {
+			warnings = append(warnings, fmt.Sprintf("%s.x is end-of-life (EOL), see https://endoflife.date/gitlab", resultMinorVersion))
+			result.EndOfLife = true
+			result.Outdated = true
+		}
+
+		if result.Version != gitlabVersion.Latest {
+			warnings = append(warnings, fmt.Sprintf("%s is outdated, latest %s version is %s", result.Version, gitlabVersion.Cycle, gitlabVersion.Latest))
+			result.Outdated = true
+		}
+	}
+
+	result.Warnings = append(result.Warnings, warnings...)
+}
+
+// fingerprintViaMetadata attempts to resolve a target's version and edition authoritatively
+// via GET /api/v4/metadata, falling back to the older GET /api/v4/version for instances that
+// predate the metadata endpoint. authoritative is true only when a usable, sufficiently recent
+// (>= 15.9) version was returned, in which case the caller can skip the hash/tag guessing flow
+// entirely. When no token was supplied and either endpoint rejects the unauthenticated request
+// with 401/403, warning is set so the caller can surface it and still fall back to the
+// manifest-based flow. A non-nil error indicates that a token was supplied and rejected, which
+// is treated as fatal since it usually means the token is misconfigured.
+func fingerprintViaMetadata(ctx context.Context, targetURL *url.URL, token string) (result Result, authoritative bool, warning string, err error) {
+	metadataURL := *targetURL
+	metadataURL.Path = metadataPath
+
+	meta, statusCode, getErr := getGitlabMetadata(ctx, metadataURL.String(), token)
+	if getErr != nil {
+		if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+			if token == "" {
+				warning = fmt.Sprintf("authenticated fingerprinting is available at %s, but no --token or GITLAB_TOKEN was supplied", metadataURL.String())
+			} else {
+				return Result{}, false, "", fmt.Errorf("the supplied token was rejected by %s: %v", metadataURL.String(), getErr)
+			}
+		}
+
+		// Older instances don't expose /api/v4/metadata at all; try /api/v4/version instead.
+		versionURL := *targetURL
+		versionURL.Path = versionPath
+		meta, statusCode, getErr = getGitlabMetadata(ctx, versionURL.String(), token)
+		if getErr != nil {
+			if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+				if token == "" {
+					if warning == "" {
+						warning = fmt.Sprintf("authenticated fingerprinting is available at %s, but no --token or GITLAB_TOKEN was supplied", versionURL.String())
+					}
+				} else {
+					return Result{}, false, "", fmt.Errorf("the supplied token was rejected by %s: %v", versionURL.String(), getErr)
+				}
+			}
+			// Neither endpoint is usable; fall back to the manifest-based flow, carrying
+			// along any warning about unauthenticated access being rejected.
+			return Result{}, false, warning, nil
+		}
+	}
+
+	if meta.Version == "" || !isVersionAtLeast(meta.Version, metadataAuthoritativeSinceMajor, metadataAuthoritativeSinceMinor) {
+		return Result{}, false, warning, nil
+	}
+
+	result.Target = targetURL.Host
+	result.Version = strings.TrimSuffix(strings.TrimSuffix(meta.Version, "-ee"), "-ce")
+	// /api/v4/version never sets Enterprise, but EE instances report their version as "X.Y.Z-ee".
+	if meta.Enterprise || strings.HasSuffix(meta.Version, "-ee") {
+		result.Edition = "enterprise"
+	} else {
+		result.Edition = "community"
+	}
+
+	return result, true, warning, nil
+}
+
+// getGitlabMetadata performs an authenticated (when token is non-empty) GET against endpoint,
+// which is expected to be either /api/v4/metadata or /api/v4/version, and returns the parsed
+// response along with the HTTP status code actually observed (useful to the caller even on error).
+func getGitlabMetadata(ctx context.Context, endpoint string, token string) (metadata GitlabMetadata, statusCode int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return
+	}
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	setUserAgent(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	statusCode = resp.StatusCode
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%s responded with %s", endpoint, resp.Status)
+		return
+	}
+
+	rawJSON, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if !json.Valid(rawJSON) {
+		err = fmt.Errorf("%s did not return valid json", endpoint)
+		return
+	}
+
+	err = json.Unmarshal(rawJSON, &metadata)
+	return
+}
+
+// isVersionAtLeast reports whether version's major.minor is >= the given major.minor.
+// It only looks at the first two dot-separated components, which is all GitLab's own
+// version gating (e.g. "this feature needs 15.9+") ever cares about.
+func isVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return false
+	}
+
+	var gotMajor, gotMinor int
+	if _, err := fmt.Sscanf(parts[0], "%d", &gotMajor); err != nil {
+		return false
+	}
+	if _, err := fmt.Sscanf(parts[1], "%d", &gotMinor); err != nil {
+		return false
+	}
+
+	if gotMajor != major {
+		return gotMajor > major
+	}
+	return gotMinor >= minor
+}
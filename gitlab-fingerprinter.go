@@ -5,55 +5,28 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net"
-	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
-const endOfLifeDateApiURL = "https://endoflife.date/api/gitlab.json"
-const hashesURL = "https://raw.githubusercontent.com/righel/gitlab-version-nse/main/gitlab_hashes.json"
-const tagsApiURL = "https://gitlab.com/api/v4/projects/278964/repository/tags"
-
-type HashDictionary map[string]struct {
-	Build    string   `json:"build"`
-	Versions []string `json:"versions"`
-}
-
-type GitlabTag struct {
-	Name          string    `json:"name"`
-	CreatedAtDate time.Time `json:"created_at"`
-}
-type GitlabTags []GitlabTag
-
-type GitlabVersion struct {
-	Cycle             string `json:"cycle"`
-	EOL               string `json:"eol"`
-	Latest            string `json:"latest"`
-	LatestReleaseDate string `json:"latestReleaseDate"`
-	ReleaseDate       string `json:"releaseDate"`
-}
-type GitlabVersions []GitlabVersion
-
-type Manifest struct {
-	Hash             string `json:"hash"`
-	LastModifiedDate time.Time
-	OutputPath       string `json:"outputPath"`
-}
-
 type Result struct {
-	Target    string   `json:"target"`
-	Version   string   `json:"version"`
-	Edition   string   `json:"edition"`
-	EndOfLife bool     `json:"end_of_life"`
-	Outdated  bool     `json:"outdated"`
-	Warnings  []string `json:"warnings"`
+	Target          string          `json:"target"`
+	Product         string          `json:"product"`
+	Version         string          `json:"version"`
+	Edition         string          `json:"edition"`
+	EndOfLife       bool            `json:"end_of_life"`
+	Outdated        bool            `json:"outdated"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	Warnings        []string        `json:"warnings"`
 }
 
 type Error struct {
@@ -67,37 +40,147 @@ type FinalOutput struct {
 	Errors  []Error  `json:"errors"`
 }
 
-// Cache GitLab API results per minor version.
-var gitlabTagsCache = make(map[string]GitlabTags)
+// Default number of targets fingerprinted concurrently, and the default per-target timeout,
+// when -concurrency/-timeout are not given.
+const defaultConcurrency = 8
+const defaultTimeout = 15 * time.Second
+
+// validFormats are the accepted -f/--format values.
+var validFormats = map[string]bool{"json": true, "ndjson": true, "csv": true, "sarif": true, "table": true}
+
+// validFailOn are the accepted --fail-on values.
+var validFailOn = map[string]bool{"none": true, "outdated": true, "eol": true, "critical": true}
+
+// validForgeTypes are the accepted -type values, one per Fingerprinter.Name().
+var validForgeTypes = map[string]bool{"gitlab": true, "gitea": true, "gerrit": true, "bitbucket": true}
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("usage: gitlab-fingerprint <url>")
+	// `update` is a subcommand, not a flag, and must be dispatched before flag.Parse() sees it.
+	if len(os.Args) > 1 && os.Args[1] == "update" {
+		if err := runSelfUpdate(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var (
+		token        string
+		concurrency  int
+		timeout      int
+		cache        cacheConfig
+		forgeType    string
+		inputPath    string
+		outputPath   string
+		format       string
+		failOn       string
+		userAgentArg string
+		insecure     bool
+		proxy        string
+		minSeverity  string
+		checkUpdate  bool
+		showVersion  bool
+	)
+
+	// A --token flag (or GITLAB_TOKEN env var) lets us query /api/v4/metadata
+	// authoritatively instead of guessing the version from the webpack manifest hash.
+	flag.StringVar(&token, "token", os.Getenv("GITLAB_TOKEN"), "GitLab personal access token, used to fingerprint authoritatively via /api/v4/metadata")
+	flag.IntVar(&concurrency, "concurrency", defaultConcurrency, "number of targets to fingerprint concurrently")
+	flag.IntVar(&timeout, "timeout", int(defaultTimeout.Seconds()), "per-target timeout in seconds")
+	flag.StringVar(&cache.dir, "cache-dir", defaultCacheDir(), "directory to cache HTTP responses in")
+	flag.DurationVar(&cache.ttl, "cache-ttl", defaultCacheTTL, "how long a cached response is used before being revalidated")
+	flag.BoolVar(&cache.disable, "no-cache", false, "disable the on-disk cache")
+	flag.StringVar(&forgeType, "type", "", "only probe this forge: gitlab, gitea, gerrit or bitbucket")
+
+	flag.StringVar(&inputPath, "i", "", "read targets, one per line, from FILE (use '-' for stdin)")
+	flag.StringVar(&inputPath, "input", "", "read targets, one per line, from FILE (use '-' for stdin)")
+	flag.StringVar(&outputPath, "o", "", "write output to FILE instead of stdout")
+	flag.StringVar(&outputPath, "output", "", "write output to FILE instead of stdout")
+	flag.StringVar(&format, "f", "json", "output format: json, ndjson, csv, sarif or table")
+	flag.StringVar(&format, "format", "json", "output format: json, ndjson, csv, sarif or table")
+	flag.StringVar(&failOn, "fail-on", "none", "exit with a non-zero status when a result matches: none, outdated, eol or critical")
+	flag.StringVar(&minSeverity, "min-severity", "low", "lowest vulnerability severity to report: low, medium, high or critical")
+	flag.StringVar(&userAgentArg, "user-agent", "", "User-Agent header to send on every request")
+	flag.BoolVar(&insecure, "insecure", false, "skip TLS certificate verification")
+	flag.StringVar(&proxy, "proxy", "", "HTTP/HTTPS proxy URL to route requests through")
+	flag.BoolVar(&checkUpdate, "check-update", os.Getenv("GITLAB_FINGERPRINTER_CHECK_UPDATE") == "1", "check for a newer release once every 24h (or set GITLAB_FINGERPRINTER_CHECK_UPDATE=1)")
+	flag.BoolVar(&showVersion, "version", false, "print the version and exit")
+
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "A GitLab Fingerprinting tool by Jeroen Swen (https://github.com/jersec/gitlab-fingerprinter)")
+		fmt.Fprintln(os.Stderr, "usage: gitlab-fingerprint [flags] <url> <url> <url>")
+		fmt.Fprintln(os.Stderr, "Examples:")
+		fmt.Fprintln(os.Stderr, "  gitlab-fingerprinter https://gitlab.foo.com")
+		fmt.Fprintln(os.Stderr, "  gitlab-fingerprinter -f table https://gitlab.example.com gitlab.example.foo")
+		fmt.Fprintln(os.Stderr, "  gitlab-fingerprinter -i targets.txt -o results.json --fail-on eol")
+		fmt.Fprintln(os.Stderr, "  gitlab-fingerprinter update")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Flags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if showVersion {
+		fmt.Println(version)
 		return
 	}
 
-	// Display help info on certain arguments.
-	for _, arg := range os.Args {
-		if arg == "-h" || arg == "--help" || arg == "-v" || arg == "--version" {
-			fmt.Println("A GitLab Fingerprinting tool by Jeroen Swen (https://github.com/jersec/gitlab-fingerprinter)")
-			fmt.Println("usage: gitlab-fingerprint <url> <url> <url>")
-			fmt.Println("Examples:")
-			fmt.Println("gitlab-fingerprinter https://gitlab.foo.com")
-			fmt.Println("gitlab-fingerprinter https://gitlab.example.com gitlab.example.foo http://git.example.bar")
-			return
+	if !validFormats[format] {
+		fmt.Fprintf(os.Stderr, "invalid --format %q: must be one of json, ndjson, csv, sarif, table\n", format)
+		os.Exit(2)
+	}
+	if !validFailOn[failOn] {
+		fmt.Fprintf(os.Stderr, "invalid --fail-on %q: must be one of none, outdated, eol, critical\n", failOn)
+		os.Exit(2)
+	}
+	if _, ok := severityRank[minSeverity]; !ok {
+		fmt.Fprintf(os.Stderr, "invalid --min-severity %q: must be one of low, medium, high, critical\n", minSeverity)
+		os.Exit(2)
+	}
+	if concurrency < 1 {
+		fmt.Fprintf(os.Stderr, "invalid -concurrency %d: must be at least 1\n", concurrency)
+		os.Exit(2)
+	}
+	if forgeType != "" && !validForgeTypes[strings.ToLower(forgeType)] {
+		fmt.Fprintf(os.Stderr, "invalid -type %q: must be one of gitlab, gitea, gerrit, bitbucket\n", forgeType)
+		os.Exit(2)
+	}
+
+	if err := configureHTTPClient(insecure, proxy); err != nil {
+		log.Fatal(err)
+	}
+	userAgent = userAgentArg
+
+	maybeCheckForUpdate(checkUpdate, cache.dir)
+
+	args := flag.Args()
+	if inputPath != "" {
+		targetsFromFile, err := readTargetsFromFile(inputPath)
+		if err != nil {
+			log.Fatalf("failed to read --input %q: %v", inputPath, err)
 		}
+		args = append(args, targetsFromFile...)
+	}
+
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	out := os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			log.Fatalf("failed to create --output %q: %v", outputPath, err)
+		}
+		defer file.Close()
+		out = file
 	}
 
 	var FinalOutput FinalOutput
 
 	// Process URLs.
 	var targetURLs []*url.URL
-	for i, arg := range os.Args {
-		// Skip the first argument.
-		if i == 0 {
-			continue
-		}
-
+	for _, arg := range args {
 		// If no scheme exists, we add one.
 		if !strings.HasPrefix(arg, "http://") && !strings.HasPrefix(arg, "https://") {
 			arg = "https://" + arg
@@ -125,347 +208,157 @@ func main() {
 			continue
 		}
 
-		// Set path to the public GitLab webpack manifest file.
-		targetURL.Path = "/assets/webpack/manifest.json"
-
 		// Add to target list.
 		targetURLs = append(targetURLs, targetURL)
 	}
 
 	// Retrieve GitLab hash dictionary from https://github.com/righel/gitlab-version-nse.
-	hashDictionary, err := getHashDictionary()
+	hashDictionary, err := getHashDictionary(cache)
 	if err != nil {
 		err = fmt.Errorf("failed to retrieve GitLab hash dictionary from https://github.com/righel/gitlab-version-nse: %v", err)
 		log.Fatal(err)
 	}
 
 	// Retrieve GitLab versions info from endoflife.date API.
-	gitlabVersionsInfo, err := getGitlabVersionsInfo()
+	gitlabVersionsInfo, err := getGitlabVersionsInfo(cache)
 	if err != nil {
 		err = fmt.Errorf("error retrieving GitLab product information from endoflife.date API: %v", err)
 		log.Fatal(err)
 	}
 
-	// Iterate through all targets.
-	for _, targetURL := range targetURLs {
-		manifest, err := getManifest(targetURL.String())
-		if err != nil {
-			var newError Error
-			newError.Target = targetURL.Host
-			newError.Error = "Failed to fingerprint target"
-			newError.Details = err.Error()
-			FinalOutput.Errors = append(FinalOutput.Errors, newError)
-			continue
-		}
+	// Load the advisories used to enrich results with known CVEs; getAdvisories queries no
+	// source and returns none, so no result gets Vulnerabilities. This is a best-effort addition on top of an otherwise-successful fingerprint, so a
+	// failure here is logged and the run continues with CVE enrichment simply disabled.
+	advisories, err := getAdvisories(cache)
+	if err != nil {
+		log.Printf("warning: failed to retrieve the GitLab Advisory Database index, continuing without CVE enrichment: %v", err)
+		advisories = nil
+	}
 
-		// If there is no mention of gitlab in the outputPath, the Manifest does not belong to a GitLab installation.
-		if !strings.Contains(manifest.OutputPath, "gitlab") {
-			var newError Error
-			newError.Target = targetURL.Host
-			newError.Error = "Target is not a GitLab installation"
-			err = fmt.Errorf("the outputPath in %s has no mention of 'gitlab' in it", targetURL)
-			newError.Details = err.Error()
-			FinalOutput.Errors = append(FinalOutput.Errors, newError)
-			err = nil
-			continue
+	// Probe forges in this order unless --type pins it to one of them.
+	var fingerprinters []Fingerprinter
+	for _, fp := range []Fingerprinter{
+		&GitLabFingerprinter{Token: token, Cache: cache, HashDictionary: hashDictionary, GitlabVersionsInfo: gitlabVersionsInfo, Advisories: advisories, MinSeverity: minSeverity},
+		&GiteaFingerprinter{},
+		&GerritFingerprinter{},
+		&BitbucketFingerprinter{},
+	} {
+		if forgeType == "" || strings.EqualFold(forgeType, fp.Name()) {
+			fingerprinters = append(fingerprinters, fp)
 		}
+	}
 
-		// Prepare a target result.
-		var result Result
-
-		result.Target = targetURL.Host
-
-		// Iterate over hashes.
-		var hashFound bool
-		for dictionaryHash, info := range hashDictionary {
-			if dictionaryHash == manifest.Hash {
-				hashFound = true
-				switch info.Build {
-				case "gitlab-ee":
-					result.Edition = "enterprise"
-				case "gitlab-ce":
-					result.Edition = "community"
-				default:
-					result.Edition = "unknown"
-					var newError Error
-					newError.Target = targetURL.Host
-					newError.Error = "Could not determine Edition"
-					newError.Details = fmt.Sprintf("the following edition was returned in the hash results: %s", info.Build)
-					FinalOutput.Errors = append(FinalOutput.Errors, newError)
-				}
-
-				// If more than one version is returned we will try to guess the versions further.
-				if len(info.Versions) == 1 {
-					for _, version := range info.Versions {
-						result.Version = version
-					}
+	// Fingerprint all targets concurrently using a bounded worker pool: jobs is the target
+	// discovery stage feeding `concurrency` fingerprinting workers, whose results are funnelled
+	// back through outputMutex into the FinalOutput aggregation stage.
+	jobs := make(chan *url.URL)
+	var outputMutex sync.Mutex
+	var workers sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for targetURL := range jobs {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+				result, err := dispatchTarget(ctx, targetURL, fingerprinters)
+				cancel()
+
+				outputMutex.Lock()
+				if err != nil {
+					FinalOutput.Errors = append(FinalOutput.Errors, Error{
+						Target:  targetURL.Host,
+						Error:   "Failed to fingerprint target",
+						Details: err.Error(),
+					})
 				} else {
-					// Find the Tag where the creation date is before the Manifest Last-Modified date and closest to it.
-					var closestDate time.Time
-					var closestDateDifference time.Duration
-					var closestDateTag string
-
-					// Check if multiple minor versions are returned. Chance of this happening is neglectible, but handle this situation regardless.
-					minorVersionsMap := make(map[string]bool)
-					var resultMinorVersion string
-
-					for _, version := range info.Versions {
-						versionParts := strings.Split(version, ".")
-						parsedMinorVersion := strings.Join(versionParts[:2], ".")
-						minorVersionsMap[parsedMinorVersion] = true
-						resultMinorVersion = parsedMinorVersion
-					}
-
-					if len(minorVersionsMap) > 1 {
-						var newError Error
-						newError.Target = targetURL.Host
-						newError.Error = "Could not determine exact version"
-						newError.Details = fmt.Sprintf("multiple minor versions were returned: %s", info.Versions)
-						FinalOutput.Errors = append(FinalOutput.Errors, newError)
-					} else {
-						tags, err := getTagsForMinorVersion(resultMinorVersion)
-						if err != nil {
-							log.Fatal(err)
-						}
-
-						for _, tag := range tags {
-							if tag.CreatedAtDate.Before(manifest.LastModifiedDate) {
-								difference := manifest.LastModifiedDate.Sub(tag.CreatedAtDate)
-
-								if closestDate.IsZero() || difference < closestDateDifference {
-									closestDate = tag.CreatedAtDate
-									closestDateDifference = difference
-									closestDateTag = tag.Name
-								}
-							}
-						}
-
-						result.Version = strings.Replace(strings.Replace(closestDateTag, "v", "", -1), "-ee", "", -1)
-					}
-				}
-				warnings := []string{}
-
-				// Check if version is Outdated or EOL by using the endoflife.date data.
-				resultVersionParts := strings.Split(result.Version, ".")
-				resultMinorVersion := strings.Join(resultVersionParts[:2], ".")
-				for _, gitlabVersion := range gitlabVersionsInfo {
-					if gitlabVersion.Cycle == resultMinorVersion {
-						eolDate, err := time.Parse("2006-01-02", gitlabVersion.EOL)
-						if err != nil {
-							fmt.Println("Error parsing date:", err)
-							os.Exit(1)
-						}
-
-						currentDate := time.Now().Format("2006-01-02")
-						parsedDate, err := time.Parse("2006-01-02", currentDate)
-						if err != nil {
-							fmt.Println("Error parsing date:", err)
-							os.Exit(1)
-						}
-
-						if eolDate.Before(parsedDate) {
-							warnings = append(warnings, fmt.Sprintf("%s.x is end-of-life (EOL), see https://endoflife.date/gitlab", resultMinorVersion))
-							result.EndOfLife = true
-							result.Outdated = true
-						}
-
-						if result.Version != gitlabVersion.Latest {
-							warnings = append(warnings, fmt.Sprintf("%s is outdated, latest %s version is %s", result.Version, gitlabVersion.Cycle, gitlabVersion.Latest))
-							result.Outdated = true
-						}
-					}
+					FinalOutput.Results = append(FinalOutput.Results, result)
 				}
-
-				result.Warnings = warnings
+				outputMutex.Unlock()
 			}
-		}
-
-		// If a hash was returned, but not found in the dictionary it can mean two things:
-		if !hashFound {
-			// The hash dictionary has not been updated yet, in this case we check if the Last-Modified date is less than 24 old.
-			if manifest.LastModifiedDate.After(time.Now().Add(-24 * time.Hour)) {
-				var result Result
-				result.Target = targetURL.Host
-				result.Version = "unknown"
-				result.Edition = "unknown"
-				result.EndOfLife = false
-				result.Outdated = false
-				result.Warnings = append(result.Warnings, "Could not fingerprint the version as the hash was not found in '%s'. However, "+
-					"the installed version seems to be less than 24 hours old and is likely not indexed yet (which happens once a day). "+
-					"It's therefore safe to assume that it's running a version released in the last 24 hours.", hashesURL)
-				FinalOutput.Results = append(FinalOutput.Results, result)
-			} else {
-				// If longer than 24 hours old, the hash dictionary is no longer being updated.
-				var newError Error
-				newError.Target = targetURL.Host
-				newError.Error = "Unable to guess version of target"
-				newError.Details = fmt.Sprintf("A manifest file was found, but the hash in it (%s) was not found in '%s'. The Last-Modified "+
-					"date of the manifest file (%s) is not shorter than 24 hours. The most likely culprit for this error is that the Hashes file is no "+
-					"longer being updated. See: https://github.com/righel/gitlab-version-nse/",
-					manifest.Hash, hashesURL, manifest.LastModifiedDate)
-				FinalOutput.Errors = append(FinalOutput.Errors, newError)
-			}
-		}
-
-		FinalOutput.Results = append(FinalOutput.Results, result)
-	}
-
-	jsonOutput, err := json.MarshalIndent(FinalOutput, "", "  ")
-	if err != nil {
-		err = fmt.Errorf(fmt.Sprintf("Failed to marshal output: %v", err), err)
-		log.Fatal(err)
+		}()
 	}
-	fmt.Println(string(jsonOutput))
-
-}
 
-func getGitlabVersionsInfo() (gitlabVersions GitlabVersions, err error) {
-	resp, err := http.Get(endOfLifeDateApiURL)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("%s did not respond with a 200 OK", endOfLifeDateApiURL)
-		return
-	}
-
-	rawJSON, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return
+	for _, targetURL := range targetURLs {
+		jobs <- targetURL
 	}
+	close(jobs)
+	workers.Wait()
 
-	if !json.Valid(rawJSON) {
-		err = fmt.Errorf("%s did not return valid json", endOfLifeDateApiURL)
+	if err := writeOutput(out, FinalOutput, format); err != nil {
+		log.Fatalf("failed to write output: %v", err)
 	}
 
-	err = json.Unmarshal(rawJSON, &gitlabVersions)
-	if err != nil {
-		return
+	switch failOn {
+	case "eol":
+		if anyEndOfLife(FinalOutput.Results) {
+			os.Exit(1)
+		}
+	case "outdated":
+		if anyOutdatedOrEndOfLife(FinalOutput.Results) {
+			os.Exit(1)
+		}
+	case "critical":
+		if anyCriticalVulnerability(FinalOutput.Results) {
+			os.Exit(1)
+		}
 	}
-
-	return
 }
 
-func getHashDictionary() (hashDictionary HashDictionary, err error) {
-	resp, err := http.Get(hashesURL)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-
-	rawJSON, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-
-	if !json.Valid(rawJSON) {
-		err = fmt.Errorf("%s did not return valid json", hashesURL)
+// readTargetsFromFile reads newline-separated targets from path, skipping blank lines and lines
+// starting with "#". Passing
+// "-" reads from stdin, e.g. for `cat hosts.txt | gitlab-fingerprinter -i -`.
+func readTargetsFromFile(path string) ([]string, error) {
+	file := os.Stdin
+	if path != "-" {
+		var err error
+		file, err = os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
 	}
 
-	err = json.Unmarshal(rawJSON, &hashDictionary)
-	if err != nil {
-		return nil, err
+	var targets []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, line)
 	}
-
-	return
+	return targets, scanner.Err()
 }
 
-func getTagsForMinorVersion(minorVersion string) (gitlabTags GitlabTags, err error) {
-	// Check if the tags for the given minor version are already in the cache.
-	if cachedTags, ok := gitlabTagsCache[minorVersion]; ok {
-		return cachedTags, nil
-	}
-
-	url := tagsApiURL + "?per_page=50&search=v" + minorVersion + ".*-ee"
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("%s did not respond with a 200 OK", url)
-		return
-	}
-
-	if resp.Header.Get("content-type") != "application/json" {
-		err = fmt.Errorf("%s did not respond with JSON", url)
-		return
-	}
-
-	rawJSON, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-
-	if !json.Valid(rawJSON) {
-		err = fmt.Errorf("%s did not return valid JSON file", url)
-		return
-	}
-
-	err = json.Unmarshal(rawJSON, &gitlabTags)
-	if err != nil {
-		err = fmt.Errorf("%s did not return valid Manifest file: %v", url, err)
-		return
-	}
-
-	for tag := range gitlabTags {
-		tag := &gitlabTags[tag]
-
-		// Parse the Created At date to the correct format:
-		tag.CreatedAtDate, err = time.Parse("2006-01-02 15:04:05 -0700 MST", tag.CreatedAtDate.String())
-		if err != nil {
-			return
+// anyEndOfLife reports whether any result is for an end-of-life version.
+func anyEndOfLife(results []Result) bool {
+	for _, result := range results {
+		if result.EndOfLife {
+			return true
 		}
 	}
-
-	// Store the tags in the cache.
-	gitlabTagsCache[minorVersion] = gitlabTags
-
-	return
+	return false
 }
 
-func getManifest(url string) (manifest Manifest, err error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("likely not a GitLab installation as %s did not respond with a 200 OK", url)
-		return
-	}
-
-	rawJSON, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-
-	if !json.Valid(rawJSON) {
-		err = fmt.Errorf("likely not a GitLab installation as %s did not return valid json", url)
-		return
-	}
-
-	err = json.Unmarshal(rawJSON, &manifest)
-	if err != nil {
-		err = fmt.Errorf("likely not a GitLab installation as %s did not return a (GitLab) webpack Manifest", url)
-		return
+// anyOutdatedOrEndOfLife reports whether any result is outdated or end-of-life.
+func anyOutdatedOrEndOfLife(results []Result) bool {
+	for _, result := range results {
+		if result.Outdated || result.EndOfLife {
+			return true
+		}
 	}
+	return false
+}
 
-	lastModifiedTime, err := time.Parse("Mon, 02 Jan 2006 15:04:05 MST", resp.Header.Get("Last-Modified"))
-	if err != nil {
-		return
+// anyCriticalVulnerability reports whether any result has a critical-severity vulnerability.
+func anyCriticalVulnerability(results []Result) bool {
+	for _, result := range results {
+		for _, vulnerability := range result.Vulnerabilities {
+			if vulnerability.Severity == "critical" {
+				return true
+			}
+		}
 	}
-
-	manifest.LastModifiedDate = lastModifiedTime
-
-	return
+	return false
 }
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// writeOutput renders output in the given format ("json", "ndjson", "csv", "sarif" or "table")
+// to w. Unknown formats fall back to indented json, matching the tool's original default.
+func writeOutput(w io.Writer, output FinalOutput, format string) error {
+	switch format {
+	case "ndjson":
+		return writeNDJSON(w, output)
+	case "csv":
+		return writeCSV(w, output)
+	case "sarif":
+		return writeSARIF(w, output)
+	case "table":
+		return writeTable(w, output)
+	default:
+		jsonOutput, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(jsonOutput))
+		return err
+	}
+}
+
+// writeNDJSON emits one JSON object per line, results first then errors, for stream-friendly
+// pipelines (e.g. `jq` or log shippers that expect newline-delimited JSON).
+func writeNDJSON(w io.Writer, output FinalOutput) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range output.Results {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	for _, scanError := range output.Errors {
+		if err := encoder.Encode(scanError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV emits one row per result. Errors aren't representable as a result row, so they're
+// omitted; use ndjson or json if errors need to be inspected.
+func writeCSV(w io.Writer, output FinalOutput) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"target", "product", "version", "edition", "end_of_life", "outdated", "vulnerabilities", "warnings"}); err != nil {
+		return err
+	}
+
+	for _, result := range output.Results {
+		row := []string{
+			result.Target,
+			result.Product,
+			result.Version,
+			result.Edition,
+			fmt.Sprintf("%t", result.EndOfLife),
+			fmt.Sprintf("%t", result.Outdated),
+			joinVulnerabilities(result.Vulnerabilities),
+			joinWarnings(result.Warnings),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
+
+func joinVulnerabilities(vulnerabilities []Vulnerability) string {
+	joined := ""
+	for i, vulnerability := range vulnerabilities {
+		if i > 0 {
+			joined += "; "
+		}
+		joined += fmt.Sprintf("%s (%s)", vulnerability.ID, vulnerability.Severity)
+	}
+	return joined
+}
+
+func joinWarnings(warnings []string) string {
+	joined := ""
+	for i, warning := range warnings {
+		if i > 0 {
+			joined += "; "
+		}
+		joined += warning
+	}
+	return joined
+}
+
+// writeTable emits a human-readable, column-aligned summary of results.
+func writeTable(w io.Writer, output FinalOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TARGET\tPRODUCT\tVERSION\tEDITION\tEOL\tOUTDATED\tVULNERABILITIES")
+	for _, result := range output.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%t\t%d\n", result.Target, result.Product, result.Version, result.Edition, result.EndOfLife, result.Outdated, len(result.Vulnerabilities))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, scanError := range output.Errors {
+		fmt.Fprintf(w, "error: %s: %s: %s\n", scanError.Target, scanError.Error, scanError.Details)
+	}
+
+	return nil
+}
+
+// sarifLog is a minimal SARIF 2.1.0 document: three rules ("outdated-version", "end-of-life" and
+// "known-vulnerability") and their results, enough for most CI security-findings uploaders.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string             `json:"ruleId"`
+	Level     string             `json:"level"`
+	Message   sarifMessage       `json:"message"`
+	Locations []sarifResultPlace `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResultPlace struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// writeSARIF emits a SARIF log with one result per outdated or end-of-life target plus one per
+// matched vulnerability, so scan output can be uploaded as security findings by CI systems that
+// understand the format.
+func writeSARIF(w io.Writer, output FinalOutput) error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "gitlab-fingerprinter",
+						InformationURI: "https://github.com/jersec/gitlab-fingerprinter",
+						Rules: []sarifRule{
+							{ID: "outdated-version", Name: "OutdatedVersion"},
+							{ID: "end-of-life", Name: "EndOfLife"},
+							{ID: "known-vulnerability", Name: "KnownVulnerability"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, result := range output.Results {
+		if result.EndOfLife {
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResultFor(result, "end-of-life", "error",
+				fmt.Sprintf("%s is running %s, an end-of-life version", result.Target, result.Version)))
+		} else if result.Outdated {
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResultFor(result, "outdated-version", "warning",
+				fmt.Sprintf("%s is running %s, an outdated version", result.Target, result.Version)))
+		}
+
+		for _, vulnerability := range result.Vulnerabilities {
+			level := "warning"
+			if vulnerability.Severity == "critical" {
+				level = "error"
+			}
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResultFor(result, "known-vulnerability", level,
+				fmt.Sprintf("%s (%s) is affected by %s (%s)", result.Target, result.Version, vulnerability.ID, vulnerability.Severity)))
+		}
+	}
+
+	rawJSON, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF output: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(rawJSON))
+	return err
+}
+
+func sarifResultFor(result Result, ruleID string, level string, message string) sarifResult {
+	return sarifResult{
+		RuleID:  ruleID,
+		Level:   level,
+		Message: sarifMessage{Text: message},
+		Locations: []sarifResultPlace{
+			{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: result.Target}}},
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gitlabSemver is a parsed GitLab version, e.g. "16.5.1" or "16.5.1-ee".
+type gitlabSemver struct {
+	Major, Minor, Patch int
+	Enterprise          bool
+}
+
+// parseGitlabSemver parses GitLab's "X.Y.Z" and "X.Y.Z-ee" version scheme.
+func parseGitlabSemver(version string) (gitlabSemver, error) {
+	var parsed gitlabSemver
+
+	version, parsed.Enterprise = strings.CutSuffix(version, "-ee")
+	version, _ = strings.CutSuffix(version, "-ce")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("%q is not a valid X.Y.Z GitLab version", version)
+	}
+
+	values := make([]int, 3)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, fmt.Errorf("%q is not a valid X.Y.Z GitLab version: %v", version, err)
+		}
+		values[i] = value
+	}
+
+	parsed.Major, parsed.Minor, parsed.Patch = values[0], values[1], values[2]
+	return parsed, nil
+}
+
+// compareGitlabSemver returns -1, 0 or 1 as a is less than, equal to, or greater than b,
+// comparing major, minor and patch in order. The enterprise/community distinction is not
+// part of the ordering, matching how GitLab cuts EE and CE releases in lockstep.
+func compareGitlabSemver(a, b gitlabSemver) int {
+	switch {
+	case a.Major != b.Major:
+		return cmpInt(a.Major, b.Major)
+	case a.Minor != b.Minor:
+		return cmpInt(a.Minor, b.Minor)
+	default:
+		return cmpInt(a.Patch, b.Patch)
+	}
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// satisfiesRange reports whether version satisfies rangeExpr, a gemnasium-db style affected
+// range: comma-separated constraints are ANDed together, "||"-separated groups are ORed, e.g.
+// ">=15.0.0,<15.11.2||>=16.0.0,<16.5.1". Supported operators are >=, <=, >, < and =. Every
+// bound must be a full X.Y.Z version; any other bound makes satisfiesRange return an error.
+func satisfiesRange(version gitlabSemver, rangeExpr string) (bool, error) {
+	for _, group := range strings.Split(rangeExpr, "||") {
+		satisfied, err := satisfiesConstraintGroup(version, group)
+		if err != nil {
+			return false, err
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func satisfiesConstraintGroup(version gitlabSemver, group string) (bool, error) {
+	for _, constraint := range strings.Split(group, ",") {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" {
+			continue
+		}
+
+		operator, rawVersion, err := splitConstraint(constraint)
+		if err != nil {
+			return false, err
+		}
+
+		bound, err := parseGitlabSemver(rawVersion)
+		if err != nil {
+			return false, err
+		}
+
+		comparison := compareGitlabSemver(version, bound)
+		var satisfied bool
+		switch operator {
+		case ">=":
+			satisfied = comparison >= 0
+		case "<=":
+			satisfied = comparison <= 0
+		case ">":
+			satisfied = comparison > 0
+		case "<":
+			satisfied = comparison < 0
+		case "=":
+			satisfied = comparison == 0
+		default:
+			return false, fmt.Errorf("unsupported operator %q in constraint %q", operator, constraint)
+		}
+
+		if !satisfied {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func splitConstraint(constraint string) (operator string, version string, err error) {
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(constraint, candidate) {
+			return candidate, strings.TrimSpace(strings.TrimPrefix(constraint, candidate)), nil
+		}
+	}
+	return "", "", fmt.Errorf("constraint %q has no recognised operator", constraint)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// version is set at build time via -ldflags "-X main.version=...". "dev" marks a local build,
+// for which update checks are always considered up to date.
+var version = "dev"
+
+// gitlabReleasesAPIURL and githubReleasesAPIURL are queried, in that order, to resolve the
+// latest release; GitLab is authoritative, GitHub is a fallback for when GitLab is unreachable
+// or returns no releases.
+const gitlabReleasesAPIURL = "https://gitlab.com/api/v4/projects/jersec%2Fgitlab-fingerprinter/releases"
+const githubReleasesAPIURL = "https://api.github.com/repos/jersec/gitlab-fingerprinter/releases/latest"
+
+// checkUpdateInterval throttles the opt-in background update check to once per day.
+const checkUpdateInterval = 24 * time.Hour
+
+// releaseAsset is a single downloadable file attached to a release, normalized from either the
+// GitLab or GitHub release API shape.
+type releaseAsset struct {
+	Name string
+	URL  string
+}
+
+// release is a resolved latest release, normalized from either the GitLab or GitHub release API.
+type release struct {
+	TagName string
+	Assets  []releaseAsset
+}
+
+type gitlabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// getLatestRelease queries the GitLab releases API first, falling back to GitHub if GitLab is
+// unreachable or returns no releases.
+func getLatestRelease() (release, error) {
+	if rel, err := getLatestGitlabRelease(); err == nil {
+		return rel, nil
+	}
+
+	rel, err := getLatestGithubRelease()
+	if err != nil {
+		return release{}, fmt.Errorf("failed to resolve the latest release via GitLab or GitHub: %w", err)
+	}
+	return rel, nil
+}
+
+func getLatestGitlabRelease() (release, error) {
+	rawJSON, err := plainGet(context.Background(), gitlabReleasesAPIURL)
+	if err != nil {
+		return release{}, err
+	}
+
+	var releases []gitlabRelease
+	if err := json.Unmarshal(rawJSON, &releases); err != nil || len(releases) == 0 {
+		return release{}, fmt.Errorf("%s did not return any releases", gitlabReleasesAPIURL)
+	}
+
+	latest := releases[0]
+	rel := release{TagName: latest.TagName}
+	for _, link := range latest.Assets.Links {
+		rel.Assets = append(rel.Assets, releaseAsset{Name: link.Name, URL: link.URL})
+	}
+	return rel, nil
+}
+
+func getLatestGithubRelease() (release, error) {
+	rawJSON, err := plainGet(context.Background(), githubReleasesAPIURL)
+	if err != nil {
+		return release{}, err
+	}
+
+	var latest githubRelease
+	if err := json.Unmarshal(rawJSON, &latest); err != nil || latest.TagName == "" {
+		return release{}, fmt.Errorf("%s did not return a release", githubReleasesAPIURL)
+	}
+
+	rel := release{TagName: latest.TagName}
+	for _, asset := range latest.Assets {
+		rel.Assets = append(rel.Assets, releaseAsset{Name: asset.Name, URL: asset.BrowserDownloadURL})
+	}
+	return rel, nil
+}
+
+// isNewerVersion reports whether latest is a newer GitLab-style X.Y.Z version than current.
+// A current version of "dev" (the default when not built with -ldflags) is never outdated.
+func isNewerVersion(current string, latest string) bool {
+	if current == "dev" {
+		return false
+	}
+
+	currentSemver, err := parseGitlabSemver(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return false
+	}
+	latestSemver, err := parseGitlabSemver(strings.TrimPrefix(latest, "v"))
+	if err != nil {
+		return false
+	}
+
+	return compareGitlabSemver(latestSemver, currentSemver) > 0
+}
+
+// lastCheckedPath is the sidecar file under cacheDir used to throttle the background update
+// check to once every checkUpdateInterval.
+func lastCheckedPath(cacheDir string) string {
+	return filepath.Join(cacheDir, "update-last-checked")
+}
+
+// shouldCheckForUpdate reports whether enough time has passed since the last background
+// update check, and records the attempt so the next run waits out the full interval.
+func shouldCheckForUpdate(cacheDir string) bool {
+	path := lastCheckedPath(cacheDir)
+
+	if raw, err := os.ReadFile(path); err == nil {
+		if lastChecked, err := time.Parse(time.RFC3339, strings.TrimSpace(string(raw))); err == nil {
+			if time.Since(lastChecked) < checkUpdateInterval {
+				return false
+			}
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o755); err == nil {
+		_ = os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)), 0o644)
+	}
+
+	return true
+}
+
+// maybeCheckForUpdate prints a one-line notice on stderr when a newer release is available.
+// It is opt-in via --check-update or GITLAB_FINGERPRINTER_CHECK_UPDATE=1, and throttled to
+// once every 24h regardless of how often it's invoked.
+func maybeCheckForUpdate(enabled bool, cacheDir string) {
+	if !enabled || !shouldCheckForUpdate(cacheDir) {
+		return
+	}
+
+	rel, err := getLatestRelease()
+	if err != nil {
+		return
+	}
+
+	if isNewerVersion(version, rel.TagName) {
+		fmt.Fprintf(os.Stderr, "A new gitlab-fingerprinter release is available: %s (running %s). Run 'gitlab-fingerprinter update' to install it.\n", rel.TagName, version)
+	}
+}
+
+// runSelfUpdate implements the `update` subcommand: it resolves the latest release, downloads
+// the asset matching the running OS/arch plus its checksums.txt sibling, verifies the download's
+// SHA256, and atomically replaces the currently running binary.
+func runSelfUpdate() error {
+	rel, err := getLatestRelease()
+	if err != nil {
+		return err
+	}
+
+	if !isNewerVersion(version, rel.TagName) {
+		fmt.Printf("gitlab-fingerprinter is already up to date (%s)\n", version)
+		return nil
+	}
+
+	assetName := fmt.Sprintf("gitlab-fingerprinter_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		assetName += ".exe"
+	}
+
+	asset, ok := findAsset(rel.Assets, assetName)
+	if !ok {
+		return fmt.Errorf("release %s has no asset named %q for this platform", rel.TagName, assetName)
+	}
+
+	checksums, ok := findAsset(rel.Assets, "checksums.txt")
+	if !ok {
+		return fmt.Errorf("release %s has no checksums.txt asset", rel.TagName)
+	}
+
+	expectedSHA256, err := expectedChecksum(checksums.URL, assetName)
+	if err != nil {
+		return fmt.Errorf("failed to verify checksums for %s: %w", assetName, err)
+	}
+
+	binary, err := plainGet(context.Background(), asset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.URL, err)
+	}
+
+	sum := sha256.Sum256(binary)
+	if actualSHA256 := hex.EncodeToString(sum[:]); actualSHA256 != expectedSHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expectedSHA256, actualSHA256)
+	}
+
+	if err := replaceRunningBinary(binary); err != nil {
+		return fmt.Errorf("failed to install the update: %w", err)
+	}
+
+	fmt.Printf("Updated gitlab-fingerprinter %s -> %s\n", version, rel.TagName)
+	return nil
+}
+
+func findAsset(assets []releaseAsset, name string) (releaseAsset, bool) {
+	for _, asset := range assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return releaseAsset{}, false
+}
+
+// expectedChecksum fetches checksumsURL (the standard `sha256sum` output format, one
+// "<hash>  <filename>" line per asset) and returns the hash for assetName.
+func expectedChecksum(checksumsURL string, assetName string) (string, error) {
+	rawChecksums, err := plainGet(context.Background(), checksumsURL)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(rawChecksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == assetName {
+			return fields[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("%s has no entry for %s", checksumsURL, assetName)
+}
+
+// replaceRunningBinary writes newBinary to a temporary file next to the currently running
+// executable and atomically renames it into place, so a crash mid-download never leaves a
+// half-written binary at the real path.
+func replaceRunningBinary(newBinary []byte) error {
+	executablePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	executablePath, err = filepath.EvalSymlinks(executablePath)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(executablePath)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(executablePath), ".gitlab-fingerprinter-update-*")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := tempFile.Write(newBinary); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tempPath, info.Mode()); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, executablePath)
+}